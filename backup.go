@@ -0,0 +1,223 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// backupPagesPerStep is how many pages are copied per Backup.Step call.
+// Keeping this small means each step holds the source database's read lock
+// only briefly, so writers are not starved during a long backup.
+const backupPagesPerStep = 100
+
+// backupStepSleep is paused between steps to give writers a chance to run.
+const backupStepSleep = 10 * time.Millisecond
+
+// Backup performs a hot, non-blocking snapshot of the live database
+// (including any data still in the WAL) into dst. Unlike copying the
+// database file directly, Backup uses SQLite's online backup API, so it
+// produces a consistent snapshot even while writers are in flight.
+func (db *DB) Backup(ctx context.Context, dst string) error {
+	destDB, err := sql.Open("sqlite3", dst)
+	if err != nil {
+		return fmt.Errorf("cannot open backup destination: %w", err)
+	}
+	defer destDB.Close()
+
+	return db.backupTo(ctx, destDB)
+}
+
+// BackupTo streams a hot snapshot of the live database to w. Since SQLite's
+// backup API operates on a database connection rather than an arbitrary
+// io.Writer, BackupTo writes the backup to a temporary file and copies the
+// result to w.
+func (db *DB) BackupTo(ctx context.Context, w io.Writer) error {
+	tmp, err := os.CreateTemp("", "go-sqlite-backup-*.db")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpPath)
+
+	if err := db.Backup(ctx, tmpPath); err != nil {
+		return err
+	}
+
+	f, err := os.Open(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func (db *DB) backupTo(ctx context.Context, destDB *sql.DB) error {
+	// Read from the ro pool, not rwDB: rwDB has a single connection shared
+	// by every writer, so checking it out for the whole backup (all steps
+	// plus the sleeps between them) would starve writers for the backup's
+	// entire duration instead of just between steps.
+	srcConn, err := db.roDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	dstConn, err := destDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dstConn.Close()
+
+	var bk *sqlite3.SQLiteBackup
+	err = dstConn.Raw(func(dstRaw interface{}) error {
+		return srcConn.Raw(func(srcRaw interface{}) error {
+			dstConn := dstRaw.(*sqlite3.SQLiteConn)
+			srcConn := srcRaw.(*sqlite3.SQLiteConn)
+
+			bk, err = dstConn.Backup("main", srcConn, "main")
+			return err
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("cannot start backup: %w", err)
+	}
+	defer bk.Finish()
+
+	for {
+		done, err := bk.Step(backupPagesPerStep)
+		if err != nil {
+			return fmt.Errorf("backup step: %w", err)
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backupStepSleep):
+		}
+	}
+}
+
+// Restore atomically replaces the current database with the snapshot at
+// src. It first waits for any in-flight transactions to finish, then closes
+// the write and read pools so no connection observes a half-replaced file,
+// copies src into place, drops any stale WAL/SHM sidecar files left by the
+// previous database, and reopens. ctx bounds both the wait and the copy, so
+// a slow or stuck Restore can be cancelled or timed out.
+func (db *DB) Restore(ctx context.Context, src string) error {
+	if db.rwDB == nil {
+		return fmt.Errorf("db not open")
+	}
+
+	if err := db.waitForNoTx(ctx); err != nil {
+		return fmt.Errorf("cannot restore with transactions in flight: %w", err)
+	}
+
+	if err := db.roDB.Close(); err != nil {
+		return fmt.Errorf("cannot close read pool: %w", err)
+	}
+	if err := db.rwDB.Close(); err != nil {
+		return fmt.Errorf("cannot close write pool: %w", err)
+	}
+
+	if err := copyFile(ctx, src, db.DSN); err != nil {
+		return fmt.Errorf("cannot restore snapshot: %w", err)
+	}
+	os.Remove(db.WALPath())
+	os.Remove(db.shmPath())
+
+	return db.Open()
+}
+
+// waitForNoTx blocks until every transaction started through BeginTx,
+// WithTx, or BeginReadTx has committed or rolled back, or ctx is done,
+// whichever comes first. Restore calls this before closing the pools so a
+// writer mid-transaction on the single rwDB connection can't still be
+// writing to the file while copyFile replaces it underneath.
+func (db *DB) waitForNoTx(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		db.txWG.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// copyFile copies src to dst by writing to a temp file in dst's directory
+// and renaming it into place, so a reader of dst never observes a
+// partially-written file and a crash mid-copy never leaves dst truncated.
+func copyFile(ctx context.Context, src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".restore-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once the rename below succeeds
+
+	if err := copyWithContext(ctx, tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, dst)
+}
+
+// copyChunkSize bounds how much copyWithContext copies per read, so ctx
+// cancellation is noticed promptly during a large restore instead of only
+// after the whole file has been copied.
+const copyChunkSize = 1 << 20
+
+func copyWithContext(ctx context.Context, dst io.Writer, src io.Reader) error {
+	buf := make([]byte, copyChunkSize)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		n, readErr := src.Read(buf)
+		if n > 0 {
+			if _, err := dst.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
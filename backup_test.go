@@ -0,0 +1,237 @@
+package sqlite_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/unixmonks/go-sqlite"
+)
+
+func TestDB_BackupRestore(t *testing.T) {
+	dir := t.TempDir()
+
+	db := sqlite.NewDB(filepath.Join(dir, "orig.db"))
+	if err := db.Open(); err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	migrationFS := fstest.MapFS{
+		"migration/00001_init.sql": &fstest.MapFile{
+			Data: []byte(`CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT);`),
+		},
+	}
+	if err := db.Migrate(migrationFS); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	insert := func(name string) {
+		tx, err := db.BeginTx(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("failed to begin tx: %v", err)
+		}
+		if _, err := tx.Exec(`INSERT INTO items (name) VALUES (?)`, name); err != nil {
+			tx.Rollback()
+			t.Fatalf("failed to insert: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+	}
+	insert("before-backup")
+
+	backupPath := filepath.Join(dir, "backup.db")
+	if err := db.Backup(context.Background(), backupPath); err != nil {
+		t.Fatalf("failed to backup: %v", err)
+	}
+
+	backupDB := sqlite.NewDB(backupPath)
+	if err := backupDB.Open(); err != nil {
+		t.Fatalf("failed to open backup: %v", err)
+	}
+	defer backupDB.Close()
+
+	readTx, err := backupDB.BeginReadTx(context.Background())
+	if err != nil {
+		t.Fatalf("failed to begin read tx on backup: %v", err)
+	}
+	var count int
+	if err := readTx.QueryRow(`SELECT COUNT(*) FROM items`).Scan(&count); err != nil {
+		t.Fatalf("failed to query backup: %v", err)
+	}
+	readTx.Rollback()
+	if count != 1 {
+		t.Fatalf("expected 1 item in backup, got %d", count)
+	}
+
+	// Data written after the backup must not appear once we restore to it.
+	insert("after-backup")
+
+	if err := db.Restore(context.Background(), backupPath); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	defer db.Close()
+
+	readTx, err = db.BeginReadTx(context.Background())
+	if err != nil {
+		t.Fatalf("failed to begin read tx after restore: %v", err)
+	}
+	defer readTx.Rollback()
+	if err := readTx.QueryRow(`SELECT COUNT(*) FROM items`).Scan(&count); err != nil {
+		t.Fatalf("failed to query after restore: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 item after restore, got %d", count)
+	}
+}
+
+// TestDB_Backup_DoesNotStarveWriters verifies that a concurrent write
+// transaction isn't blocked for the entire duration of a Backup call: the
+// backup source connection must come from the ro pool, not the single rw
+// connection every writer shares.
+func TestDB_Backup_DoesNotStarveWriters(t *testing.T) {
+	dir := t.TempDir()
+
+	db := sqlite.NewDB(filepath.Join(dir, "orig.db"))
+	if err := db.Open(); err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	migrationFS := fstest.MapFS{
+		"migration/00001_init.sql": &fstest.MapFile{
+			Data: []byte(`CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT);`),
+		},
+	}
+	if err := db.Migrate(migrationFS); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	backupDone := make(chan error, 1)
+	go func() {
+		backupDone <- db.Backup(context.Background(), filepath.Join(dir, "backup.db"))
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+
+	start := time.Now()
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to begin tx during backup: %v", err)
+	}
+	elapsed := time.Since(start)
+	tx.Rollback()
+
+	if elapsed > 50*time.Millisecond {
+		t.Fatalf("BeginTx blocked for %v while a backup was running; writers should not wait on the backup source connection", elapsed)
+	}
+
+	if err := <-backupDone; err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+}
+
+// TestDB_Restore_WaitsForInFlightTx verifies that Restore blocks until a
+// concurrent write transaction finishes rather than racing copyFile against
+// it, and that it respects ctx cancellation while waiting.
+func TestDB_Restore_WaitsForInFlightTx(t *testing.T) {
+	dir := t.TempDir()
+
+	db := sqlite.NewDB(filepath.Join(dir, "orig.db"))
+	if err := db.Open(); err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	migrationFS := fstest.MapFS{
+		"migration/00001_init.sql": &fstest.MapFile{
+			Data: []byte(`CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT);`),
+		},
+	}
+	if err := db.Migrate(migrationFS); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	backupPath := filepath.Join(dir, "backup.db")
+	if err := db.Backup(context.Background(), backupPath); err != nil {
+		t.Fatalf("failed to backup: %v", err)
+	}
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to begin tx: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := db.Restore(ctx, backupPath); err == nil {
+		t.Fatal("expected Restore to time out while a transaction is in flight")
+	} else if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit tx: %v", err)
+	}
+
+	if err := db.Restore(context.Background(), backupPath); err != nil {
+		t.Fatalf("failed to restore once tx finished: %v", err)
+	}
+}
+
+func TestDB_BackupTo(t *testing.T) {
+	dir := t.TempDir()
+
+	db := sqlite.NewDB(filepath.Join(dir, "orig.db"))
+	if err := db.Open(); err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	migrationFS := fstest.MapFS{
+		"migration/00001_init.sql": &fstest.MapFile{
+			Data: []byte(`CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT);`),
+		},
+	}
+	if err := db.Migrate(migrationFS); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := db.BackupTo(context.Background(), &buf); err != nil {
+		t.Fatalf("failed to backup to writer: %v", err)
+	}
+	if buf.Len() == 0 {
+		t.Fatal("expected non-empty backup stream")
+	}
+
+	dumpedPath := filepath.Join(dir, "dumped.db")
+	if err := os.WriteFile(dumpedPath, buf.Bytes(), 0600); err != nil {
+		t.Fatalf("failed to write dumped backup: %v", err)
+	}
+
+	dumpedDB := sqlite.NewDB(dumpedPath)
+	if err := dumpedDB.Open(); err != nil {
+		t.Fatalf("failed to open dumped backup: %v", err)
+	}
+	defer dumpedDB.Close()
+
+	readTx, err := dumpedDB.BeginReadTx(context.Background())
+	if err != nil {
+		t.Fatalf("failed to begin read tx on dumped backup: %v", err)
+	}
+	defer readTx.Rollback()
+	var n int
+	if err := readTx.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'items'`).Scan(&n); err != nil {
+		t.Fatalf("failed to query dumped backup: %v", err)
+	}
+	if n != 1 {
+		t.Fatal("expected items table to exist in dumped backup")
+	}
+}
@@ -0,0 +1,94 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// CheckpointMode controls how aggressively Checkpoint flushes the WAL back
+// into the main database file. The values mirror SQLite's wal_checkpoint
+// PRAGMA modes.
+type CheckpointMode string
+
+const (
+	CheckpointPassive  CheckpointMode = "PASSIVE"
+	CheckpointFull     CheckpointMode = "FULL"
+	CheckpointRestart  CheckpointMode = "RESTART"
+	CheckpointTruncate CheckpointMode = "TRUNCATE"
+)
+
+// Checkpoint runs PRAGMA wal_checkpoint(mode) and returns the three values
+// SQLite reports: whether the checkpoint was blocked by a busy reader or
+// writer, the size of the WAL in frames, and how many of those frames were
+// checkpointed.
+func (db *DB) Checkpoint(ctx context.Context, mode CheckpointMode) (busy bool, log int, checkpointed int, err error) {
+	var busyInt int
+	row := db.rwDB.QueryRowContext(ctx, fmt.Sprintf(`PRAGMA wal_checkpoint(%s)`, mode))
+	if err := row.Scan(&busyInt, &log, &checkpointed); err != nil {
+		return false, 0, 0, fmt.Errorf("wal_checkpoint: %w", err)
+	}
+	return busyInt != 0, log, checkpointed, nil
+}
+
+// WALPath returns the path to the write-ahead log sidecar file for this
+// database. It is only meaningful for file-backed databases.
+func (db *DB) WALPath() string {
+	return db.DSN + "-wal"
+}
+
+func (db *DB) shmPath() string {
+	return db.DSN + "-shm"
+}
+
+// removeWALIfEmpty removes the -wal and -shm sidecar files left behind on
+// Close if they are empty, so an idle database doesn't leave stray
+// zero-byte files in its data directory.
+func (db *DB) removeWALIfEmpty() {
+	for _, path := range []string{db.WALPath(), db.shmPath()} {
+		if fi, err := os.Stat(path); err == nil && fi.Size() == 0 {
+			os.Remove(path)
+		}
+	}
+}
+
+// startCheckpointer launches the background checkpointer goroutine if
+// CheckpointInterval is configured. It is a no-op otherwise.
+func (db *DB) startCheckpointer() {
+	if db.CheckpointInterval <= 0 {
+		return
+	}
+	go db.runCheckpointer()
+}
+
+// runCheckpointer periodically truncates the WAL once it grows past
+// CheckpointPagesThreshold, for long-running writers where the WAL would
+// otherwise grow unbounded. It exits when the DB is closed.
+func (db *DB) runCheckpointer() {
+	ticker := time.NewTicker(db.CheckpointInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-db.ctx.Done():
+			return
+		case <-ticker.C:
+			db.maybeCheckpoint()
+		}
+	}
+}
+
+func (db *DB) maybeCheckpoint() {
+	if db.CheckpointPagesThreshold <= 0 {
+		// No threshold configured: never checkpoint rather than running an
+		// unconditional TRUNCATE on every tick.
+		return
+	}
+
+	_, log, _, err := db.Checkpoint(db.ctx, CheckpointPassive)
+	if err != nil || log <= db.CheckpointPagesThreshold {
+		return
+	}
+	db.Checkpoint(db.ctx, CheckpointTruncate)
+}
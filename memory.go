@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+)
+
+// NewMemoryDB returns a *DB backed by a private, isolated in-memory
+// database, hiding the DSN detail needed to get there. It is equivalent to
+// NewDB(":memory:"): either way the *DB gets a database that no other *DB
+// in the process can observe.
+func NewMemoryDB() *DB {
+	return NewDB(":memory:")
+}
+
+// openMemory opens an in-memory database using the memdb VFS rather than
+// "file::memory:?cache=shared". Shared-cache memory mode gives every
+// NewDB(":memory:") in the process the same database, which breaks test
+// isolation and any multi-tenant use; memdb instead gives each *DB a
+// private database at a randomly generated path, while still letting the
+// read-only pool hold multiple concurrent connections against it.
+func (db *DB) openMemory() (err error) {
+	path := "/mem-" + randomHex(16)
+
+	rwDSN := fmt.Sprintf("file:%s?vfs=memdb&_foreign_keys=on&mode=rw&_txlock=immediate", path)
+	if db.rwDB, err = sql.Open("sqlite3", rwDSN); err != nil {
+		return err
+	}
+	db.rwDB.SetMaxOpenConns(1)
+	db.rwDB.SetMaxIdleConns(1)
+	db.rwDB.SetConnMaxLifetime(0)
+	db.rwDB.SetConnMaxIdleTime(0)
+
+	roDSN := fmt.Sprintf("file:%s?vfs=memdb&_foreign_keys=on&mode=ro&_txlock=deferred", path)
+	if db.roDB, err = sql.Open("sqlite3", roDSN); err != nil {
+		db.rwDB.Close()
+		return err
+	}
+	db.roDB.SetMaxOpenConns(10)
+	db.roDB.SetMaxIdleConns(5)
+
+	return nil
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n/2)
+	if _, err := rand.Read(buf); err != nil {
+		panic("sqlite: crypto/rand unavailable: " + err.Error())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,104 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/unixmonks/go-sqlite"
+)
+
+func TestNewMemoryDB_Isolated(t *testing.T) {
+	migrationFS := fstest.MapFS{
+		"migration/00001_init.sql": &fstest.MapFile{
+			Data: []byte(`CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT);`),
+		},
+	}
+
+	dbA := sqlite.NewMemoryDB()
+	if err := dbA.Open(); err != nil {
+		t.Fatalf("failed to open dbA: %v", err)
+	}
+	defer dbA.Close()
+	if err := dbA.Migrate(migrationFS); err != nil {
+		t.Fatalf("failed to migrate dbA: %v", err)
+	}
+
+	tx, err := dbA.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to begin tx on dbA: %v", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO items (name) VALUES ('only-in-a')`); err != nil {
+		tx.Rollback()
+		t.Fatalf("failed to insert into dbA: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit dbA: %v", err)
+	}
+
+	dbB := sqlite.NewMemoryDB()
+	if err := dbB.Open(); err != nil {
+		t.Fatalf("failed to open dbB: %v", err)
+	}
+	defer dbB.Close()
+	if err := dbB.Migrate(migrationFS); err != nil {
+		t.Fatalf("failed to migrate dbB: %v", err)
+	}
+
+	readTx, err := dbB.BeginReadTx(context.Background())
+	if err != nil {
+		t.Fatalf("failed to begin read tx on dbB: %v", err)
+	}
+	defer readTx.Rollback()
+
+	var count int
+	if err := readTx.QueryRow(`SELECT COUNT(*) FROM items`).Scan(&count); err != nil {
+		t.Fatalf("failed to query dbB: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected dbB to be isolated from dbA, but found %d rows", count)
+	}
+}
+
+func TestNewMemoryDB_ReadPoolSeesWrites(t *testing.T) {
+	db := sqlite.NewMemoryDB()
+	if err := db.Open(); err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	migrationFS := fstest.MapFS{
+		"migration/00001_init.sql": &fstest.MapFile{
+			Data: []byte(`CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT);`),
+		},
+	}
+	if err := db.Migrate(migrationFS); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to begin tx: %v", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO items (name) VALUES ('item1')`); err != nil {
+		tx.Rollback()
+		t.Fatalf("failed to insert: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	readTx, err := db.BeginReadTx(context.Background())
+	if err != nil {
+		t.Fatalf("failed to begin read tx: %v", err)
+	}
+	defer readTx.Rollback()
+
+	var count int
+	if err := readTx.QueryRow(`SELECT COUNT(*) FROM items`).Scan(&count); err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 item visible to the ro pool, got %d", count)
+	}
+}
@@ -0,0 +1,419 @@
+package sqlite
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var migrationFilenameRe = regexp.MustCompile(`^(\d+)_(.+)\.sql$`)
+
+// migration describes one versioned migration step, with its up file and,
+// optionally, a paired down file for reversing it.
+type migration struct {
+	version  int
+	name     string
+	upFile   string
+	downFile string
+}
+
+// Migrate runs all pending up migrations from the provided filesystem,
+// migrating to the latest known version.
+//
+// Migration files live in a "migration" subdirectory and are named with a
+// numeric prefix for ordering. Two forms are supported:
+//
+//	migration/00001_init.sql                  (up-only, no down migration)
+//	migration/00002_users.up.sql
+//	migration/00002_users.down.sql
+//
+// Each migration runs once and is tracked in a migrations table; if a
+// migration that was already applied is found with different content (via
+// checksum), Migrate refuses to continue rather than silently re-running or
+// skipping it.
+func (db *DB) Migrate(migrationFS fs.FS) error {
+	migrations, err := loadMigrations(migrationFS)
+	if err != nil {
+		return err
+	}
+	if len(migrations) == 0 {
+		return nil
+	}
+	return db.MigrateTo(migrationFS, migrations[len(migrations)-1].version)
+}
+
+// MigrationVersion returns the highest applied migration version, or 0 if
+// no migrations have been applied yet.
+func (db *DB) MigrationVersion() (int, error) {
+	if err := db.ensureMigrationsTable(); err != nil {
+		return 0, err
+	}
+
+	var version sql.NullInt64
+	if err := db.rwDB.QueryRow(`SELECT MAX(version) FROM migrations`).Scan(&version); err != nil {
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
+
+// MigrateTo migrates the database up or down to targetVersion. Migrating
+// upward applies each pending .sql or .up.sql file in order inside its own
+// transaction. Migrating downward applies the .down.sql files in reverse
+// order inside BEGIN IMMEDIATE transactions; a version with no down file
+// cannot be migrated past.
+//
+// Every migration at or below the current version is checksum-verified
+// before anything else runs, even if targetVersion == current and nothing
+// ends up being applied — otherwise a no-op re-run of Migrate would never
+// notice a migration file that changed after it was applied.
+func (db *DB) MigrateTo(migrationFS fs.FS, targetVersion int) error {
+	if err := db.ensureMigrationsTable(); err != nil {
+		return err
+	}
+
+	migrations, err := loadMigrations(migrationFS)
+	if err != nil {
+		return err
+	}
+
+	current, err := db.MigrationVersion()
+	if err != nil {
+		return err
+	}
+
+	if err := db.verifyAppliedChecksums(migrationFS, migrations, current); err != nil {
+		return err
+	}
+
+	switch {
+	case targetVersion > current:
+		for _, m := range migrations {
+			if m.version <= current || m.version > targetVersion {
+				continue
+			}
+			if err := db.applyUp(migrationFS, m); err != nil {
+				return fmt.Errorf("migration error: version=%d name=%q err=%w", m.version, m.name, err)
+			}
+		}
+	case targetVersion < current:
+		for i := len(migrations) - 1; i >= 0; i-- {
+			m := migrations[i]
+			if m.version > current || m.version <= targetVersion {
+				continue
+			}
+			if err := db.applyDown(migrationFS, m); err != nil {
+				return fmt.Errorf("migration error: version=%d name=%q err=%w", m.version, m.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (db *DB) ensureMigrationsTable() error {
+	hasVersionCol, tableExists, err := db.migrationsTableShape()
+	if err != nil {
+		return err
+	}
+
+	if tableExists && !hasVersionCol {
+		if err := db.upgradeLegacyMigrationsTable(); err != nil {
+			return fmt.Errorf("cannot upgrade legacy migrations table: %w", err)
+		}
+		return nil
+	}
+
+	_, err = db.rwDB.Exec(`CREATE TABLE IF NOT EXISTS migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL,
+		checksum   TEXT NOT NULL
+	);`)
+	if err != nil {
+		return fmt.Errorf("cannot create migrations table: %w", err)
+	}
+	return nil
+}
+
+// migrationsTableShape reports whether a migrations table already exists
+// and, if so, whether it has the version-tracking columns this package
+// added, as opposed to the original name-only table (`migrations (name
+// TEXT PRIMARY KEY)`) created by earlier versions of Migrate.
+func (db *DB) migrationsTableShape() (hasVersionCol bool, tableExists bool, err error) {
+	var n int
+	if err := db.rwDB.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'migrations'`).Scan(&n); err != nil {
+		return false, false, err
+	}
+	if n == 0 {
+		return false, false, nil
+	}
+
+	rows, err := db.rwDB.Query(`PRAGMA table_info(migrations)`)
+	if err != nil {
+		return false, true, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notnull, pk int
+		var name, ctype string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &ctype, &notnull, &dflt, &pk); err != nil {
+			return false, true, err
+		}
+		if name == "version" {
+			hasVersionCol = true
+		}
+	}
+	return hasVersionCol, true, rows.Err()
+}
+
+// upgradeLegacyMigrationsTable migrates the pre-version-tracking migrations
+// table (one row per applied migration file path, no version/checksum) to
+// the current schema, so that databases migrated before down-migration
+// support shipped keep working. Legacy rows have no recorded checksum, so
+// their checksum is left blank; verifyChecksum treats a blank checksum as "trust
+// this row" and backfills it the next time Migrate runs against the real
+// migration file.
+func (db *DB) upgradeLegacyMigrationsTable() error {
+	tx, err := db.rwDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT name FROM migrations`)
+	if err != nil {
+		return err
+	}
+	var legacyNames []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		legacyNames = append(legacyNames, name)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	rows.Close()
+
+	if _, err := tx.Exec(`ALTER TABLE migrations RENAME TO migrations_legacy`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`CREATE TABLE migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL,
+		checksum   TEXT NOT NULL
+	);`); err != nil {
+		return err
+	}
+
+	now := db.Now().UTC()
+	for _, legacyName := range legacyNames {
+		version, name, _, err := parseMigrationFilename(filepath.Base(legacyName))
+		if err != nil {
+			return fmt.Errorf("cannot parse legacy migration name %q: %w", legacyName, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO migrations (version, name, applied_at, checksum) VALUES (?, ?, ?, '')`,
+			version, name, now); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`DROP TABLE migrations_legacy`); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// verifyAppliedChecksums re-reads every migration at or below the current
+// version and refuses to continue if its content no longer matches what
+// was recorded when it was applied. This runs on every Migrate/MigrateTo
+// call regardless of target version, so drift is caught even when there is
+// nothing left to apply.
+func (db *DB) verifyAppliedChecksums(migrationFS fs.FS, migrations []migration, current int) error {
+	for _, m := range migrations {
+		if m.version > current || m.upFile == "" {
+			continue
+		}
+		if err := db.verifyChecksum(migrationFS, m); err != nil {
+			return fmt.Errorf("migration error: version=%d name=%q err=%w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+func (db *DB) verifyChecksum(migrationFS fs.FS, m migration) error {
+	buf, err := fs.ReadFile(migrationFS, m.upFile)
+	if err != nil {
+		return err
+	}
+	checksum := checksumOf(buf)
+
+	tx, err := db.rwDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var existing string
+	if err := tx.QueryRow(`SELECT checksum FROM migrations WHERE version = ?`, m.version).Scan(&existing); err != nil {
+		return err
+	}
+
+	switch {
+	case existing == "":
+		// Backfilled from the legacy migrations table with no recorded
+		// checksum: trust it was applied correctly and record the
+		// checksum now instead of refusing.
+		if _, err := tx.Exec(`UPDATE migrations SET checksum = ? WHERE version = ?`, checksum, m.version); err != nil {
+			return err
+		}
+		return tx.Commit()
+	case existing != checksum:
+		return fmt.Errorf("checksum mismatch: migration %d has changed since it was applied", m.version)
+	default:
+		return nil // already applied, unchanged
+	}
+}
+
+func (db *DB) applyUp(migrationFS fs.FS, m migration) error {
+	buf, err := fs.ReadFile(migrationFS, m.upFile)
+	if err != nil {
+		return err
+	}
+	checksum := checksumOf(buf)
+
+	tx, err := db.rwDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(string(buf)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO migrations (version, name, applied_at, checksum) VALUES (?, ?, ?, ?)`,
+		m.version, m.name, db.Now().UTC(), checksum); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (db *DB) applyDown(migrationFS fs.FS, m migration) error {
+	if m.downFile == "" {
+		return fmt.Errorf("no down migration for version %d", m.version)
+	}
+	buf, err := fs.ReadFile(migrationFS, m.downFile)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.rwDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("ROLLBACK; BEGIN IMMEDIATE"); err != nil {
+		return err
+	}
+
+	var n int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM migrations WHERE version = ?`, m.version).Scan(&n); err != nil {
+		return err
+	} else if n == 0 {
+		return nil // not applied
+	}
+
+	if _, err := tx.Exec(string(buf)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM migrations WHERE version = ?`, m.version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func checksumOf(buf []byte) string {
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:])
+}
+
+// loadMigrations globs migration/*.sql, pairs up/down files by version, and
+// returns them sorted ascending by version.
+func loadMigrations(migrationFS fs.FS) ([]migration, error) {
+	names, err := fs.Glob(migrationFS, "migration/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*migration)
+	for _, name := range names {
+		version, stem, direction, err := parseMigrationFilename(filepath.Base(name))
+		if err != nil {
+			return nil, fmt.Errorf("migration error: name=%q err=%w", name, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{version: version, name: stem}
+			byVersion[version] = m
+		}
+		switch direction {
+		case "up":
+			m.upFile = name
+		case "down":
+			m.downFile = name
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+
+	return migrations, nil
+}
+
+// parseMigrationFilename parses "00002_users.up.sql" into (2, "users",
+// "up"), "00002_users.down.sql" into (2, "users", "down"), and the legacy
+// up-only form "00001_init.sql" into (1, "init", "up").
+func parseMigrationFilename(base string) (version int, name string, direction string, err error) {
+	match := migrationFilenameRe.FindStringSubmatch(base)
+	if match == nil {
+		return 0, "", "", fmt.Errorf("invalid migration filename: %q", base)
+	}
+
+	version, err = strconv.Atoi(match[1])
+	if err != nil {
+		return 0, "", "", fmt.Errorf("invalid migration version: %q", base)
+	}
+
+	name = match[2]
+	direction = "up"
+	switch {
+	case strings.HasSuffix(name, ".up"):
+		name = strings.TrimSuffix(name, ".up")
+	case strings.HasSuffix(name, ".down"):
+		name = strings.TrimSuffix(name, ".down")
+		direction = "down"
+	}
+
+	return version, name, direction, nil
+}
@@ -0,0 +1,174 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/unixmonks/go-sqlite"
+)
+
+func TestDB_MigrateTo_UpAndDown(t *testing.T) {
+	db := sqlite.NewDB(":memory:")
+	if err := db.Open(); err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	migrationFS := fstest.MapFS{
+		"migration/00001_init.sql": &fstest.MapFile{
+			Data: []byte(`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);`),
+		},
+		"migration/00002_posts.up.sql": &fstest.MapFile{
+			Data: []byte(`CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT);`),
+		},
+		"migration/00002_posts.down.sql": &fstest.MapFile{
+			Data: []byte(`DROP TABLE posts;`),
+		},
+	}
+
+	if err := db.Migrate(migrationFS); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	version, err := db.MigrationVersion()
+	if err != nil {
+		t.Fatalf("failed to get version: %v", err)
+	}
+	if version != 2 {
+		t.Fatalf("expected version 2, got %d", version)
+	}
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to begin tx: %v", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO posts (title) VALUES ('hello')`); err != nil {
+		tx.Rollback()
+		t.Fatalf("failed to insert into posts: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	if err := db.MigrateTo(migrationFS, 1); err != nil {
+		t.Fatalf("failed to migrate down: %v", err)
+	}
+
+	version, err = db.MigrationVersion()
+	if err != nil {
+		t.Fatalf("failed to get version after down migration: %v", err)
+	}
+	if version != 1 {
+		t.Fatalf("expected version 1 after down migration, got %d", version)
+	}
+
+	readTx, err := db.BeginReadTx(context.Background())
+	if err != nil {
+		t.Fatalf("failed to begin read tx: %v", err)
+	}
+	defer readTx.Rollback()
+	var n int
+	if err := readTx.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'posts'`).Scan(&n); err != nil {
+		t.Fatalf("failed to query sqlite_master: %v", err)
+	}
+	if n != 0 {
+		t.Fatal("expected posts table to be dropped after down migration")
+	}
+}
+
+func TestDB_MigrateTo_NoDownFileRefused(t *testing.T) {
+	db := sqlite.NewDB(":memory:")
+	if err := db.Open(); err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	migrationFS := fstest.MapFS{
+		"migration/00001_init.sql": &fstest.MapFile{
+			Data: []byte(`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);`),
+		},
+	}
+	if err := db.Migrate(migrationFS); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	if err := db.MigrateTo(migrationFS, 0); err == nil {
+		t.Fatal("expected error migrating below a version with no down file")
+	}
+}
+
+func TestDB_Migrate_ChecksumMismatchRefused(t *testing.T) {
+	db := sqlite.NewDB(":memory:")
+	if err := db.Open(); err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Migrate(fstest.MapFS{
+		"migration/00001_init.sql": &fstest.MapFile{
+			Data: []byte(`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);`),
+		},
+	}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	err := db.Migrate(fstest.MapFS{
+		"migration/00001_init.sql": &fstest.MapFile{
+			Data: []byte(`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, email TEXT);`),
+		},
+	})
+	if err == nil {
+		t.Fatal("expected checksum mismatch error for changed migration content")
+	}
+}
+
+// TestDB_Migrate_UpgradesLegacyTable simulates a database migrated before
+// down-migration support shipped, where the migrations table only tracked
+// applied file names, and verifies Migrate upgrades it instead of failing
+// with "no such column: version".
+func TestDB_Migrate_UpgradesLegacyTable(t *testing.T) {
+	db := sqlite.NewDB(":memory:")
+	if err := db.Open(); err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	migrationFS := fstest.MapFS{
+		"migration/00001_init.sql": &fstest.MapFile{
+			Data: []byte(`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);`),
+		},
+	}
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to begin tx: %v", err)
+	}
+	if _, err := tx.Exec(`CREATE TABLE migrations (name TEXT PRIMARY KEY);`); err != nil {
+		tx.Rollback()
+		t.Fatalf("failed to seed legacy migrations table: %v", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO migrations (name) VALUES ('migration/00001_init.sql')`); err != nil {
+		tx.Rollback()
+		t.Fatalf("failed to seed legacy migrations row: %v", err)
+	}
+	if _, err := tx.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);`); err != nil {
+		tx.Rollback()
+		t.Fatalf("failed to seed users table: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit seed: %v", err)
+	}
+
+	if err := db.Migrate(migrationFS); err != nil {
+		t.Fatalf("failed to migrate legacy database: %v", err)
+	}
+
+	version, err := db.MigrationVersion()
+	if err != nil {
+		t.Fatalf("failed to get version: %v", err)
+	}
+	if version != 1 {
+		t.Fatalf("expected version 1 after upgrading legacy table, got %d", version)
+	}
+}
@@ -0,0 +1,64 @@
+package sqlite
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// RetryPolicy controls how BeginTx and WithTx retry a write transaction
+// that fails to acquire the write lock with SQLITE_BUSY or SQLITE_LOCKED.
+// SQLite's own busy handler makes BEGIN IMMEDIATE wait up to _busy_timeout
+// for the lock; RetryPolicy applies once that wait has run out and the
+// attempt has failed with a busy error.
+type RetryPolicy struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	// Jitter is the fraction of each delay to randomize, e.g. 0.2 for
+	// +/-20%.
+	Jitter float64
+}
+
+// DefaultRetryPolicy is used by BeginTx and WithTx when DB.WriteRetry is
+// the zero value.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  5,
+	InitialDelay: 10 * time.Millisecond,
+	MaxDelay:     1 * time.Second,
+	Jitter:       0.2,
+}
+
+func (p RetryPolicy) orDefault() RetryPolicy {
+	if p.MaxAttempts == 0 {
+		return DefaultRetryPolicy
+	}
+	return p
+}
+
+// delay returns the backoff delay before the next attempt, given the
+// attempt number (0-indexed) that just failed.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.InitialDelay << attempt
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		jitter := float64(d) * p.Jitter
+		d = d - time.Duration(jitter) + time.Duration(rand.Float64()*2*jitter)
+	}
+	return d
+}
+
+// isBusyErr reports whether err is a SQLITE_BUSY or SQLITE_LOCKED error
+// from mattn/go-sqlite3, the two codes that indicate a lock wait rather
+// than a real failure.
+func isBusyErr(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/unixmonks/go-sqlite"
+)
+
+// TestDB_BeginTx_And_WithTx_DoNotCompoundRetries holds SQLITE_BUSY
+// contention from a connection outside the package, long enough to exceed
+// the hardcoded _busy_timeout, and checks that BeginTx and WithTx each
+// spend exactly RetryPolicy.MaxAttempts-1 retries of their own rather than
+// WithTx retrying on top of BeginTx's already-retrying BEGIN IMMEDIATE.
+func TestDB_BeginTx_And_WithTx_DoNotCompoundRetries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("exercises real SQLITE_BUSY contention and takes over ten seconds")
+	}
+
+	// Each attempt first waits out the 5000ms _busy_timeout baked into every
+	// connection before SQLite reports a busy error. With MaxAttempts 2 the
+	// lock must outlast both attempts plus the backoff between them, or the
+	// last attempt acquires the lock once it is released and succeeds.
+	const holdDuration = 10500 * time.Millisecond
+
+	runWithContention := func(t *testing.T, fn func(db *sqlite.DB)) {
+		t.Helper()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "test.db")
+
+		db := sqlite.NewDB(path)
+		if err := db.Open(); err != nil {
+			t.Fatalf("failed to open db: %v", err)
+		}
+		defer db.Close()
+
+		locker, err := sql.Open("sqlite3", path+"?_busy_timeout=0")
+		if err != nil {
+			t.Fatalf("failed to open locker: %v", err)
+		}
+		defer locker.Close()
+		if _, err := locker.Exec("BEGIN IMMEDIATE"); err != nil {
+			t.Fatalf("failed to acquire write lock: %v", err)
+		}
+
+		released := make(chan struct{})
+		go func() {
+			time.Sleep(holdDuration)
+			locker.Exec("COMMIT")
+			close(released)
+		}()
+		defer func() { <-released }()
+
+		db.WriteRetry = sqlite.RetryPolicy{
+			MaxAttempts:  2,
+			InitialDelay: 10 * time.Millisecond,
+			MaxDelay:     50 * time.Millisecond,
+		}
+
+		fn(db)
+	}
+
+	t.Run("BeginTx", func(t *testing.T) {
+		t.Parallel()
+		runWithContention(t, func(db *sqlite.DB) {
+			_, err := db.BeginTx(context.Background(), nil)
+			if err == nil {
+				t.Fatal("expected BeginTx to fail while the writer lock is held")
+			}
+			if got := db.Stats().TxRetries; got != 1 {
+				t.Fatalf("TxRetries = %d, want 1 (MaxAttempts-1)", got)
+			}
+		})
+	})
+
+	t.Run("WithTx", func(t *testing.T) {
+		t.Parallel()
+		runWithContention(t, func(db *sqlite.DB) {
+			err := db.WithTx(context.Background(), func(tx *sqlite.Tx) error { return nil })
+			if err == nil {
+				t.Fatal("expected WithTx to fail while the writer lock is held")
+			}
+			if got := db.Stats().TxRetries; got != 1 {
+				t.Fatalf("TxRetries = %d, want 1; a higher count means WithTx compounded BeginTx's own retries", got)
+			}
+		})
+	})
+}
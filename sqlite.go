@@ -5,10 +5,10 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
-	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -20,8 +20,27 @@ type DB struct {
 	ctx    context.Context
 	cancel func()
 
+	stats      dbStats
+	expvarOnce sync.Once
+	txWG       sync.WaitGroup // counts transactions in flight, so Restore can wait for them to finish
+
 	DSN string
 	Now func() time.Time
+
+	// CheckpointInterval, if non-zero, starts a background goroutine on
+	// Open that periodically checks the WAL size and truncates it once it
+	// grows past CheckpointPagesThreshold.
+	CheckpointInterval time.Duration
+	// CheckpointPagesThreshold is the WAL size, in pages, above which the
+	// background checkpointer runs a TRUNCATE checkpoint. The background
+	// checkpointer never runs a checkpoint while this is zero or
+	// negative, even if CheckpointInterval is set.
+	CheckpointPagesThreshold int
+
+	// WriteRetry controls how BEGIN IMMEDIATE is retried when it fails
+	// with SQLITE_BUSY or SQLITE_LOCKED. The zero value uses
+	// DefaultRetryPolicy.
+	WriteRetry RetryPolicy
 }
 
 func NewDB(dsn string) *DB {
@@ -39,7 +58,12 @@ func (db *DB) Open() (err error) {
 	}
 
 	if db.DSN == ":memory:" {
-		return db.openMemory()
+		if err := db.openMemory(); err != nil {
+			return err
+		}
+		db.expvarOnce.Do(db.registerExpvar)
+		db.startCheckpointer()
+		return nil
 	}
 
 	if err := os.MkdirAll(filepath.Dir(db.DSN), 0700); err != nil {
@@ -64,80 +88,12 @@ func (db *DB) Open() (err error) {
 	db.roDB.SetMaxOpenConns(10)
 	db.roDB.SetMaxIdleConns(5)
 
-	return nil
-}
-
-func (db *DB) openMemory() (err error) {
-	dsn := "file::memory:?cache=shared&_foreign_keys=on"
-
-	if db.rwDB, err = sql.Open("sqlite3", dsn); err != nil {
-		return err
-	}
-	db.rwDB.SetMaxOpenConns(1)
-	db.rwDB.SetMaxIdleConns(1)
-	db.rwDB.SetConnMaxLifetime(0)
-	db.rwDB.SetConnMaxIdleTime(0)
-
-	if db.roDB, err = sql.Open("sqlite3", dsn); err != nil {
-		db.rwDB.Close()
-		return err
-	}
-	db.roDB.SetMaxOpenConns(10)
-	db.roDB.SetMaxIdleConns(5)
-
-	return nil
-}
-
-// Migrate runs all SQL migrations from the provided filesystem.
-// Migration files should be in a "migration" subdirectory and named with a numeric prefix
-// for ordering (e.g., "migration/00001_init.sql", "migration/00002_users.sql").
-// Each migration runs once and is tracked in a migrations table.
-func (db *DB) Migrate(migrationFS fs.FS) error {
-	if _, err := db.rwDB.Exec(`CREATE TABLE IF NOT EXISTS migrations (name TEXT PRIMARY KEY);`); err != nil {
-		return fmt.Errorf("cannot create migrations table: %w", err)
-	}
-
-	names, err := fs.Glob(migrationFS, "migration/*.sql")
-	if err != nil {
-		return err
-	}
-	sort.Strings(names)
+	db.expvarOnce.Do(db.registerExpvar)
+	db.startCheckpointer()
 
-	for _, name := range names {
-		if err := db.migrateFile(migrationFS, name); err != nil {
-			return fmt.Errorf("migration error: name=%q err=%w", name, err)
-		}
-	}
 	return nil
 }
 
-func (db *DB) migrateFile(migrationFS fs.FS, name string) error {
-	tx, err := db.rwDB.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	var n int
-	if err := tx.QueryRow(`SELECT COUNT(*) FROM migrations WHERE name = ?`, name).Scan(&n); err != nil {
-		return err
-	} else if n != 0 {
-		return nil
-	}
-
-	if buf, err := fs.ReadFile(migrationFS, name); err != nil {
-		return err
-	} else if _, err := tx.Exec(string(buf)); err != nil {
-		return err
-	}
-
-	if _, err := tx.Exec(`INSERT INTO migrations (name) VALUES (?)`, name); err != nil {
-		return err
-	}
-
-	return tx.Commit()
-}
-
 func (db *DB) Close() error {
 	db.cancel()
 
@@ -152,15 +108,43 @@ func (db *DB) Close() error {
 			errs = append(errs, err)
 		}
 	}
+	db.removeWALIfEmpty()
 	if len(errs) > 0 {
 		return errs[0]
 	}
 	return nil
 }
 
-// BeginTx starts a read-write transaction using BEGIN IMMEDIATE.
+// BeginTx starts a read-write transaction using BEGIN IMMEDIATE, retrying
+// with exponential backoff (per DB.WriteRetry) if another writer already
+// holds the lock.
 // Use this for any operation that may write to the database.
 func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	policy := db.WriteRetry.orDefault()
+
+	for attempt := 0; ; attempt++ {
+		tx, err := db.beginTxOnce(ctx, opts)
+		if err == nil {
+			return tx, nil
+		}
+
+		if !isBusyErr(err) || attempt >= policy.MaxAttempts-1 {
+			return nil, err
+		}
+
+		atomic.AddInt64(&db.stats.txRetries, 1)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(policy.delay(attempt)):
+		}
+	}
+}
+
+// beginTxOnce makes a single, non-retrying attempt at BEGIN IMMEDIATE. It
+// is the shared primitive BeginTx and WithTx each wrap in their own retry
+// loop, so that retrying one never means retrying the other underneath it.
+func (db *DB) beginTxOnce(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
 	tx, err := db.rwDB.BeginTx(ctx, opts)
 	if err != nil {
 		return nil, err
@@ -171,6 +155,8 @@ func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
 		return nil, err
 	}
 
+	atomic.AddInt64(&db.stats.writeTx, 1)
+	db.txWG.Add(1)
 	return &Tx{
 		Tx:  tx,
 		db:  db,
@@ -178,6 +164,37 @@ func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
 	}, nil
 }
 
+// WithTx runs fn inside a write transaction, retrying the whole "begin +
+// run fn" attempt with the same backoff policy as BeginTx if it fails with
+// SQLITE_BUSY or SQLITE_LOCKED. It opens transactions with the
+// non-retrying beginTxOnce, not BeginTx, so the two don't compound into a
+// multiplicative retry budget. fn's transaction is rolled back and a fresh
+// one opened between attempts, so fn must be safe to run more than once.
+func (db *DB) WithTx(ctx context.Context, fn func(*Tx) error) error {
+	policy := db.WriteRetry.orDefault()
+
+	for attempt := 0; ; attempt++ {
+		tx, err := db.beginTxOnce(ctx, nil)
+		if err == nil {
+			if err = fn(tx); err == nil {
+				return tx.Commit()
+			}
+			tx.Rollback()
+		}
+
+		if !isBusyErr(err) || attempt >= policy.MaxAttempts-1 {
+			return err
+		}
+
+		atomic.AddInt64(&db.stats.txRetries, 1)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(policy.delay(attempt)):
+		}
+	}
+}
+
 // BeginReadTx starts a read-only transaction using BEGIN DEFERRED.
 // Use this for operations that only read from the database.
 func (db *DB) BeginReadTx(ctx context.Context) (*Tx, error) {
@@ -186,6 +203,9 @@ func (db *DB) BeginReadTx(ctx context.Context) (*Tx, error) {
 		return nil, err
 	}
 
+	atomic.AddInt64(&db.stats.readTx, 1)
+	db.txWG.Add(1)
+
 	return &Tx{
 		Tx:  tx,
 		db:  db,
@@ -195,8 +215,9 @@ func (db *DB) BeginReadTx(ctx context.Context) (*Tx, error) {
 
 type Tx struct {
 	*sql.Tx
-	db  *DB
-	now time.Time
+	db   *DB
+	now  time.Time
+	done sync.Once
 }
 
 // Now returns the transaction's timestamp, frozen at transaction start.
@@ -204,6 +225,56 @@ func (tx *Tx) Now() time.Time {
 	return tx.now
 }
 
+// Commit commits the transaction. It wraps sql.Tx.Commit to mark the
+// transaction finished, so Restore's wait for in-flight transactions
+// doesn't hang waiting on one that already committed.
+func (tx *Tx) Commit() error {
+	defer tx.finish()
+	return tx.Tx.Commit()
+}
+
+// Rollback rolls back the transaction. It wraps sql.Tx.Rollback to mark the
+// transaction finished, so Restore's wait for in-flight transactions
+// doesn't hang waiting on one that already rolled back.
+func (tx *Tx) Rollback() error {
+	defer tx.finish()
+	return tx.Tx.Rollback()
+}
+
+// finish marks tx as no longer in flight. It is idempotent since callers
+// commonly both defer Rollback and call Commit on the success path.
+func (tx *Tx) finish() {
+	tx.done.Do(tx.db.txWG.Done)
+}
+
+// ExecContext executes a query without returning rows, tracking execution
+// counters on the parent DB so callers get observability without
+// instrumenting every call site. Exec, inherited from the embedded *sql.Tx,
+// does not go through this method and is not counted.
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	res, err := tx.Tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		atomic.AddInt64(&tx.db.stats.executionErrors, 1)
+	} else {
+		atomic.AddInt64(&tx.db.stats.executions, 1)
+	}
+	return res, err
+}
+
+// QueryContext executes a query that returns rows, tracking query counters
+// on the parent DB so callers get observability without instrumenting
+// every call site. Query, QueryRow and QueryRowContext, inherited from the
+// embedded *sql.Tx, do not go through this method and are not counted.
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := tx.Tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		atomic.AddInt64(&tx.db.stats.queryErrors, 1)
+	} else {
+		atomic.AddInt64(&tx.db.stats.queries, 1)
+	}
+	return rows, err
+}
+
 type NullTime time.Time
 
 func (n *NullTime) Scan(value interface{}) error {
@@ -0,0 +1,95 @@
+package sqlite
+
+import (
+	"database/sql"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+)
+
+// expvarSeq disambiguates the expvar map names of distinct *DB instances
+// that share a DSN. A *DB's pointer address is not a safe substitute: once
+// a *DB is closed and garbage collected, a later *DB can be allocated at
+// the same address, and expvar panics on a republished name.
+var expvarSeq int64
+
+// Stats is a point-in-time snapshot of a DB's query counters and
+// connection pool stats.
+type Stats struct {
+	Executions      int64
+	ExecutionErrors int64
+	Queries         int64
+	QueryErrors     int64
+	ReadTx          int64
+	WriteTx         int64
+	TxRetries       int64
+
+	RW sql.DBStats
+	RO sql.DBStats
+}
+
+// dbStats holds the atomic counters backing Stats and the DB's expvar map.
+type dbStats struct {
+	executions      int64
+	executionErrors int64
+	queries         int64
+	queryErrors     int64
+	readTx          int64
+	writeTx         int64
+	txRetries       int64
+}
+
+// Stats returns a snapshot of the DB's query and connection pool counters.
+func (db *DB) Stats() Stats {
+	return Stats{
+		Executions:      atomic.LoadInt64(&db.stats.executions),
+		ExecutionErrors: atomic.LoadInt64(&db.stats.executionErrors),
+		Queries:         atomic.LoadInt64(&db.stats.queries),
+		QueryErrors:     atomic.LoadInt64(&db.stats.queryErrors),
+		ReadTx:          atomic.LoadInt64(&db.stats.readTx),
+		WriteTx:         atomic.LoadInt64(&db.stats.writeTx),
+		TxRetries:       atomic.LoadInt64(&db.stats.txRetries),
+		RW:              db.rwDB.Stats(),
+		RO:              db.roDB.Stats(),
+	}
+}
+
+// registerExpvar publishes db's counters and pool stats under an
+// expvar.Map namespaced by DSN, so operators can scrape them alongside the
+// rest of the process's expvar output without instrumenting every call
+// site.
+//
+// Memory databases are skipped: NewMemoryDB is meant for opening many
+// short-lived, private databases (e.g. one per test), and expvar has no
+// way to unpublish a name, so registering one per memory DB would leak
+// unbounded global state for a pattern that is expected to churn.
+func (db *DB) registerExpvar() {
+	if db.DSN == ":memory:" {
+		return
+	}
+
+	seq := atomic.AddInt64(&expvarSeq, 1)
+	m := expvar.NewMap(fmt.Sprintf("sqlite:%s:%d", db.DSN, seq))
+
+	m.Set("executions", expvar.Func(func() interface{} { return atomic.LoadInt64(&db.stats.executions) }))
+	m.Set("execution_errors", expvar.Func(func() interface{} { return atomic.LoadInt64(&db.stats.executionErrors) }))
+	m.Set("queries", expvar.Func(func() interface{} { return atomic.LoadInt64(&db.stats.queries) }))
+	m.Set("query_errors", expvar.Func(func() interface{} { return atomic.LoadInt64(&db.stats.queryErrors) }))
+	m.Set("read_tx", expvar.Func(func() interface{} { return atomic.LoadInt64(&db.stats.readTx) }))
+	m.Set("write_tx", expvar.Func(func() interface{} { return atomic.LoadInt64(&db.stats.writeTx) }))
+	m.Set("tx_retries", expvar.Func(func() interface{} { return atomic.LoadInt64(&db.stats.txRetries) }))
+
+	m.Set("rw_max_open_connections", expvar.Func(func() interface{} { return db.rwDB.Stats().MaxOpenConnections }))
+	m.Set("rw_open_connections", expvar.Func(func() interface{} { return db.rwDB.Stats().OpenConnections }))
+	m.Set("rw_in_use", expvar.Func(func() interface{} { return db.rwDB.Stats().InUse }))
+	m.Set("rw_idle", expvar.Func(func() interface{} { return db.rwDB.Stats().Idle }))
+	m.Set("rw_wait_count", expvar.Func(func() interface{} { return db.rwDB.Stats().WaitCount }))
+	m.Set("rw_wait_duration_ms", expvar.Func(func() interface{} { return db.rwDB.Stats().WaitDuration.Milliseconds() }))
+
+	m.Set("ro_max_open_connections", expvar.Func(func() interface{} { return db.roDB.Stats().MaxOpenConnections }))
+	m.Set("ro_open_connections", expvar.Func(func() interface{} { return db.roDB.Stats().OpenConnections }))
+	m.Set("ro_in_use", expvar.Func(func() interface{} { return db.roDB.Stats().InUse }))
+	m.Set("ro_idle", expvar.Func(func() interface{} { return db.roDB.Stats().Idle }))
+	m.Set("ro_wait_count", expvar.Func(func() interface{} { return db.roDB.Stats().WaitCount }))
+	m.Set("ro_wait_duration_ms", expvar.Func(func() interface{} { return db.roDB.Stats().WaitDuration.Milliseconds() }))
+}
@@ -0,0 +1,87 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/unixmonks/go-sqlite"
+)
+
+func TestDB_Stats(t *testing.T) {
+	db := sqlite.NewDB(":memory:")
+	if err := db.Open(); err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	migrationFS := fstest.MapFS{
+		"migration/00001_init.sql": &fstest.MapFile{
+			Data: []byte(`CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT);`),
+		},
+	}
+	if err := db.Migrate(migrationFS); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to begin tx: %v", err)
+	}
+	if _, err := tx.ExecContext(context.Background(), `INSERT INTO items (name) VALUES ('item1')`); err != nil {
+		tx.Rollback()
+		t.Fatalf("failed to insert: %v", err)
+	}
+	if _, err := tx.QueryContext(context.Background(), `SELECT * FROM items`); err != nil {
+		tx.Rollback()
+		t.Fatalf("failed to query: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	readTx, err := db.BeginReadTx(context.Background())
+	if err != nil {
+		t.Fatalf("failed to begin read tx: %v", err)
+	}
+	readTx.Rollback()
+
+	stats := db.Stats()
+	if stats.WriteTx != 1 {
+		t.Errorf("WriteTx = %d, want 1", stats.WriteTx)
+	}
+	if stats.ReadTx != 1 {
+		t.Errorf("ReadTx = %d, want 1", stats.ReadTx)
+	}
+	if stats.Executions != 1 {
+		t.Errorf("Executions = %d, want 1", stats.Executions)
+	}
+	if stats.Queries != 1 {
+		t.Errorf("Queries = %d, want 1", stats.Queries)
+	}
+	if stats.RW.MaxOpenConnections != 1 {
+		t.Errorf("RW.MaxOpenConnections = %d, want 1", stats.RW.MaxOpenConnections)
+	}
+}
+
+func TestDB_Stats_TracksErrors(t *testing.T) {
+	db := sqlite.NewDB(":memory:")
+	if err := db.Open(); err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to begin tx: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(context.Background(), `INSERT INTO does_not_exist (name) VALUES ('x')`); err == nil {
+		t.Fatal("expected error inserting into nonexistent table")
+	}
+
+	if got := db.Stats().ExecutionErrors; got != 1 {
+		t.Errorf("ExecutionErrors = %d, want 1", got)
+	}
+}